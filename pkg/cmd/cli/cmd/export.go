@@ -1,9 +1,11 @@
 package cmd
 
 import (
+	"crypto/rsa"
 	"fmt"
 	"io"
 	"os"
+	"sort"
 
 	"github.com/spf13/cobra"
 
@@ -50,7 +52,7 @@ to generate the API structure for a template to which you can add parameters and
 )
 
 func NewCmdExport(fullName string, f *clientcmd.Factory, in io.Reader, out io.Writer) *cobra.Command {
-	exporter := &defaultExporter{}
+	exporter := newExporterRegistry()
 	var filenames []string
 	cmd := &cobra.Command{
 		Use:     "export RESOURCE/NAME ... [options]",
@@ -74,6 +76,25 @@ func NewCmdExport(fullName string, f *clientcmd.Factory, in io.Reader, out io.Wr
 
 	cmd.Flags().Bool("all", true, "DEPRECATED: all is ignored, specifying a resource without a name selects all the instances of that resource")
 	cmd.Flags().MarkDeprecated("all", "all is ignored because specifying a resource without a name selects all the instances of that resource")
+
+	cmd.Flags().Bool("encrypt-secrets", false, "Replace exported Secret objects with a sealed EncryptedSecret using --encrypt-key.")
+	cmd.Flags().String("encrypt-key", "", "Path to a PEM encoded public key used to seal exported Secrets.")
+	cmd.Flags().String("encrypt-provider", "sealed-secrets", "The sealing scheme to record on sealed Secrets: sealed-secrets, age, or pgp.")
+	cmd.Flags().Bool("decrypt-secrets", false, "Replace EncryptedSecret objects with the plaintext Secret using --decrypt-key.")
+	cmd.Flags().String("decrypt-key", "", "Path to a PEM encoded private key used to unseal EncryptedSecret objects.")
+
+	cmd.Flags().StringSlice("context", []string{}, "Export from this kubeconfig context. May be repeated to export from multiple clusters at once.")
+	cmd.Flags().Bool("all-contexts", false, "Export from every context in the kubeconfig, instead of just the current or --context ones.")
+	cmd.Flags().Bool("group-by-cluster", false, "When exporting from multiple contexts, emit one List per cluster inside the outer template instead of merging all objects together.")
+	cmd.Flags().String("merge-strategy", "error", "How to resolve the same object being exported from more than one context: first, last, or error.")
+
+	cmd.Flags().String("validate-schema", "off", "Validate exported objects against the server's published OpenAPI schema: strict, warn, or off.")
+
+	cmd.Flags().Bool("ordered", false, "Sort exported objects into a deterministic install order (namespaces and CRDs first, then workloads, then any other kinds) so the output can be re-applied reliably.")
+
+	cmd.Flags().Bool("explain-export", false, "Instead of exporting, print a table of (kind, field, action, reason) describing what export would change.")
+	cmd.Flags().String("exporter-plugin", "", "Path to an out-of-process executable that receives each object as JSON on stdin and returns the transformed object (or OMIT) on stdout.")
+
 	cmdutil.AddPrinterFlags(cmd)
 	return cmd
 }
@@ -88,6 +109,72 @@ func RunExport(f *clientcmd.Factory, exporter Exporter, in io.Reader, out io.Wri
 		return cmdutil.UsageError(cmd, "--exact and --raw may not both be specified")
 	}
 
+	encryptSecrets := cmdutil.GetFlagBool(cmd, "encrypt-secrets")
+	decryptSecrets := cmdutil.GetFlagBool(cmd, "decrypt-secrets")
+	if encryptSecrets && decryptSecrets {
+		return cmdutil.UsageError(cmd, "--encrypt-secrets and --decrypt-secrets may not both be specified")
+	}
+	encryptProvider := cmdutil.GetFlagString(cmd, "encrypt-provider")
+	if _, known := secretEncryptProviders[encryptProvider]; encryptSecrets && !known {
+		return cmdutil.UsageError(cmd, "--encrypt-provider must be one of: sealed-secrets, age, pgp")
+	}
+	var (
+		encryptPub         *rsa.PublicKey
+		encryptFingerprint string
+		decryptPriv        *rsa.PrivateKey
+	)
+	if encryptSecrets {
+		encryptKey := cmdutil.GetFlagString(cmd, "encrypt-key")
+		if len(encryptKey) == 0 {
+			return cmdutil.UsageError(cmd, "--encrypt-secrets requires --encrypt-key")
+		}
+		if encryptProvider != "sealed-secrets" {
+			return cmdutil.UsageError(cmd, fmt.Sprintf("--encrypt-provider=%s is not yet implemented", encryptProvider))
+		}
+		var err error
+		encryptPub, encryptFingerprint, err = loadPublicKey(encryptKey)
+		if err != nil {
+			return err
+		}
+	}
+	if decryptSecrets {
+		decryptKey := cmdutil.GetFlagString(cmd, "decrypt-key")
+		if len(decryptKey) == 0 {
+			return cmdutil.UsageError(cmd, "--decrypt-secrets requires --decrypt-key")
+		}
+		var err error
+		decryptPriv, err = loadPrivateKey(decryptKey)
+		if err != nil {
+			return err
+		}
+	}
+
+	contexts := cmdutil.GetFlagStringSlice(cmd, "context")
+	allContexts := cmdutil.GetFlagBool(cmd, "all-contexts")
+	groupByCluster := cmdutil.GetFlagBool(cmd, "group-by-cluster")
+	mergeStrategy := cmdutil.GetFlagString(cmd, "merge-strategy")
+	if !mergeStrategies[mergeStrategy] {
+		return cmdutil.UsageError(cmd, "--merge-strategy must be one of: first, last, error")
+	}
+	if groupByCluster && len(asTemplate) == 0 {
+		return cmdutil.UsageError(cmd, "--group-by-cluster requires --as-template")
+	}
+	validateSchema := cmdutil.GetFlagString(cmd, "validate-schema")
+	if !validateSchemaModes[validateSchema] {
+		return cmdutil.UsageError(cmd, "--validate-schema must be one of: strict, warn, off")
+	}
+	if allContexts {
+		all, err := f.OpenShiftClientConfig.RawConfig()
+		if err != nil {
+			return err
+		}
+		contexts = contexts[:0]
+		for name := range all.Contexts {
+			contexts = append(contexts, name)
+		}
+		sort.Strings(contexts)
+	}
+
 	clientConfig, err := f.ClientConfig()
 	if err != nil {
 		return err
@@ -99,24 +186,65 @@ func RunExport(f *clientcmd.Factory, exporter Exporter, in io.Reader, out io.Wri
 		return err
 	}
 
-	mapper, typer := f.Object()
-	b := resource.NewBuilder(mapper, typer, f.ClientMapperForCommand()).
-		NamespaceParam(cmdNamespace).DefaultNamespace().AllNamespaces(allNamespaces).
-		FilenameParam(explicit, filenames...).
-		SelectorParam(selector).
-		ResourceTypeOrNameArgs(true, args...).
-		Flatten()
+	buildForFactory := func(factory *clientcmd.Factory) *resource.Builder {
+		mapper, typer := factory.Object()
+		return resource.NewBuilder(mapper, typer, factory.ClientMapperForCommand()).
+			NamespaceParam(cmdNamespace).DefaultNamespace().AllNamespaces(allNamespaces).
+			FilenameParam(explicit, filenames...).
+			SelectorParam(selector).
+			ResourceTypeOrNameArgs(true, args...).
+			Flatten()
+	}
 
-	one := false
-	infos, err := b.Do().IntoSingular(&one).Infos()
-	if err != nil {
-		return err
+	var (
+		infos     []*resource.Info
+		one       bool
+		byContext map[string][]*resource.Info
+	)
+	if len(contexts) > 0 {
+		byContext, err = infosForContexts(f, contexts, buildForFactory)
+		if err != nil {
+			return err
+		}
+		if groupByCluster {
+			// Each cluster's objects are kept and printed independently (see
+			// groupInfosByCluster below), so there is nothing to merge or
+			// conflict-detect across contexts: every Info from every context
+			// still needs to flow through Export/encrypt/validate below.
+			for _, context := range contexts {
+				infos = append(infos, byContext[context]...)
+			}
+		} else {
+			infos, err = mergeContextInfos(byContext, contexts, mergeStrategy)
+			if err != nil {
+				return err
+			}
+		}
+	} else {
+		infos, err = buildForFactory(f).Do().IntoSingular(&one).Infos()
+		if err != nil {
+			return err
+		}
 	}
 
 	if len(infos) == 0 {
 		return fmt.Errorf("no resources found - nothing to export")
 	}
 
+	if pluginPath := cmdutil.GetFlagString(cmd, "exporter-plugin"); len(pluginPath) > 0 {
+		if registry, ok := exporter.(*exporterRegistry); ok {
+			registry.SetPlugin(newPluginExporter(pluginPath))
+		}
+	}
+
+	if cmdutil.GetFlagBool(cmd, "explain-export") {
+		registry, ok := exporter.(*exporterRegistry)
+		if !ok {
+			return fmt.Errorf("--explain-export requires the default exporter")
+		}
+		return printExportExplanation(infos, registry, exact, out)
+	}
+
 	if !raw {
 		newInfos := []*resource.Info{}
 		errs := []error{}
@@ -133,18 +261,80 @@ func RunExport(f *clientcmd.Factory, exporter Exporter, in io.Reader, out io.Wri
 			return utilerrors.NewAggregate(errs)
 		}
 		infos = newInfos
+		if groupByCluster {
+			dropOmittedFromContext(byContext, infos)
+		}
+	}
+
+	if encryptSecrets || decryptSecrets {
+		errs := []error{}
+		for _, info := range infos {
+			var (
+				sealed runtime.Object
+				err    error
+			)
+			switch {
+			case encryptSecrets:
+				sealed, err = sealSecret(info.Object, encryptProvider, encryptPub, encryptFingerprint)
+			case decryptSecrets:
+				sealed, err = unsealSecret(info.Object, decryptPriv)
+			}
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			info.Object = sealed
+		}
+		if len(errs) > 0 {
+			return utilerrors.NewAggregate(errs)
+		}
+	}
+
+	if err := validateExportedSchemas(f, infos, validateSchema, out); err != nil {
+		return err
+	}
+
+	ordered := cmdutil.GetFlagBool(cmd, "ordered")
+	if ordered {
+		infos = orderInfos(infos, newDefaultInstallOrder(infos))
+		for context, contextInfos := range byContext {
+			byContext[context] = orderInfos(contextInfos, newDefaultInstallOrder(contextInfos))
+		}
 	}
 
 	var result runtime.Object
 	if len(asTemplate) > 0 {
-		objects, err := resource.AsVersionedObjects(infos, outputVersion)
-		if err != nil {
-			return err
+		var objects []runtime.Object
+		if groupByCluster && byContext != nil {
+			for _, group := range groupInfosByCluster(byContext, contexts) {
+				clusterObjects, err := resource.AsVersionedObjects(group.Infos, outputVersion)
+				if err != nil {
+					return err
+				}
+				clusterList := &kapi.List{Items: clusterObjects}
+				versioned, err := kapi.Scheme.ConvertToVersion(clusterList, outputVersion)
+				if err != nil {
+					return err
+				}
+				objects = append(objects, versioned)
+			}
+		} else {
+			objects, err = resource.AsVersionedObjects(infos, outputVersion)
+			if err != nil {
+				return err
+			}
 		}
 		template := &templateapi.Template{
 			Objects: objects,
 		}
 		template.Name = asTemplate
+		if encryptSecrets {
+			template.Parameters = append(template.Parameters, templateapi.Parameter{
+				Name:        "DECRYPTION_KEY_ID",
+				Description: "Fingerprint of the private key required to decrypt sealed Secrets in this template.",
+				Value:       encryptFingerprint,
+			})
+		}
 		result, err = kapi.Scheme.ConvertToVersion(template, outputVersion)
 		if err != nil {
 			return err
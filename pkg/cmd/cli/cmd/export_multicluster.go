@@ -0,0 +1,322 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/kubectl/resource"
+	"k8s.io/kubernetes/pkg/runtime"
+	utilerrors "k8s.io/kubernetes/pkg/util/errors"
+
+	"github.com/openshift/origin/pkg/cmd/util/clientcmd"
+)
+
+// mergeStrategies lists the values --merge-strategy accepts when the same
+// object is exported from more than one context.
+var mergeStrategies = map[string]bool{
+	"first": true,
+	"last":  true,
+	"error": true,
+}
+
+// contextKey identifies a single object independent of which context it was
+// read from, so that the same object exported from two clusters can be
+// recognized as a conflict.
+type contextKey struct {
+	gvk       string
+	namespace string
+	name      string
+}
+
+// infosForContexts builds one resource.Builder per requested context and
+// runs the usual ResourceTypeOrNameArgs/FilenameParam/SelectorParam pipeline
+// against each in parallel, returning every context's infos keyed by context
+// name and tagged with a source-context annotation.
+func infosForContexts(f *clientcmd.Factory, contexts []string, build func(*clientcmd.Factory) *resource.Builder) (map[string][]*resource.Info, error) {
+	type result struct {
+		context string
+		infos   []*resource.Info
+		err     error
+	}
+
+	results := make(chan result, len(contexts))
+	var wg sync.WaitGroup
+	for _, context := range contexts {
+		wg.Add(1)
+		go func(context string) {
+			defer wg.Done()
+			contextFactory, err := f.ForContext(context)
+			if err != nil {
+				results <- result{context: context, err: err}
+				return
+			}
+			one := false
+			infos, err := build(contextFactory).Do().IntoSingular(&one).Infos()
+			if err != nil {
+				results <- result{context: context, err: err}
+				return
+			}
+			for _, info := range infos {
+				annotateSourceContext(info.Object, context)
+			}
+			results <- result{context: context, infos: infos}
+		}(context)
+	}
+	wg.Wait()
+	close(results)
+
+	byContext := map[string][]*resource.Info{}
+	errs := []error{}
+	for r := range results {
+		if r.err != nil {
+			errs = append(errs, fmt.Errorf("context %q: %v", r.context, r.err))
+			continue
+		}
+		byContext[r.context] = r.infos
+	}
+	if len(errs) > 0 {
+		return nil, utilerrors.NewAggregate(errs)
+	}
+	return byContext, nil
+}
+
+// sourceContextAnnotation is the annotation key annotateSourceContext sets.
+// It necessarily differs between otherwise-identical copies of the same
+// object read from different contexts, so diffInfos ignores it - without
+// that, every object merely present in every cluster (a shared Namespace, a
+// ServiceAccount) would always show up as a "conflict".
+const sourceContextAnnotation = "origin.openshift.io/source-context"
+
+// annotateSourceContext records which kubeconfig context an exported object
+// came from, so a merged template/list can be traced back to its cluster.
+func annotateSourceContext(obj runtime.Object, context string) {
+	objMeta, err := kapi.ObjectMetaFor(obj)
+	if err != nil {
+		return
+	}
+	if objMeta.Annotations == nil {
+		objMeta.Annotations = map[string]string{}
+	}
+	objMeta.Annotations[sourceContextAnnotation] = context
+}
+
+// mergeContextInfos flattens the per-context infos into a single ordered
+// slice, applying mergeStrategy whenever the same GVK+namespace+name appears
+// under more than one context. Copies that differ in nothing but their
+// source-context annotation are not a conflict; the first one is kept.
+func mergeContextInfos(byContext map[string][]*resource.Info, contexts []string, mergeStrategy string) ([]*resource.Info, error) {
+	seen := map[contextKey]*resource.Info{}
+	merged := []*resource.Info{}
+	conflicts := []string{}
+
+	for _, context := range contexts {
+		for _, info := range byContext[context] {
+			key := contextKey{gvk: info.Mapping.GroupVersionKind.String(), namespace: info.Namespace, name: info.Name}
+			existing, ok := seen[key]
+			if !ok {
+				seen[key] = info
+				merged = append(merged, info)
+				continue
+			}
+			switch mergeStrategy {
+			case "first":
+				continue
+			case "last":
+				replaceInfo(merged, existing, info)
+				seen[key] = info
+			default:
+				diff := diffInfos(existing, info)
+				if len(diff) == 0 {
+					continue
+				}
+				conflicts = append(conflicts, fmt.Sprintf("%s %s/%s:\n%s", key.gvk, key.namespace, key.name, diff))
+			}
+		}
+	}
+	if len(conflicts) > 0 {
+		sort.Strings(conflicts)
+		return nil, fmt.Errorf("conflicting objects across contexts (use --merge-strategy to resolve):\n  %s", joinLines(conflicts))
+	}
+	return merged, nil
+}
+
+// diffInfos reports, one dot-path per line, every field that differs between
+// two conflicting copies of the same object read from different contexts.
+// It compares the objects' JSON form rather than their Go structs so it
+// works uniformly across every kind without type-switching. It returns an
+// empty string when the two copies do not differ.
+func diffInfos(a, b *resource.Info) string {
+	aObj, aErr := toJSONMap(a.Object)
+	bObj, bErr := toJSONMap(b.Object)
+	if aErr != nil || bErr != nil {
+		return "    (unable to compute a field diff)"
+	}
+	stripSourceContextAnnotation(aObj)
+	stripSourceContextAnnotation(bObj)
+	diffs := diffValues("", aObj, bObj)
+	if len(diffs) == 0 {
+		return ""
+	}
+	sort.Strings(diffs)
+	lines := make([]string, len(diffs))
+	for i, d := range diffs {
+		lines[i] = "    " + d
+	}
+	return joinLines(lines)
+}
+
+// stripSourceContextAnnotation deletes the source-context annotation
+// annotateSourceContext stamped on obj (in its generic JSON-map form) so it
+// doesn't register as a diff between two otherwise-identical copies of the
+// same object read from different contexts.
+func stripSourceContextAnnotation(obj map[string]interface{}) {
+	meta, ok := obj["metadata"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	annotations, ok := meta["annotations"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	delete(annotations, sourceContextAnnotation)
+	if len(annotations) == 0 {
+		delete(meta, "annotations")
+	}
+}
+
+func toJSONMap(obj runtime.Object) (map[string]interface{}, error) {
+	raw, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// diffValues walks a and b in lock-step, returning one "<path>: a -> b" line
+// per leaf value that differs, per key present on only one side, and per
+// array element present on only one side.
+func diffValues(path string, a, b interface{}) []string {
+	aMap, aIsMap := a.(map[string]interface{})
+	bMap, bIsMap := b.(map[string]interface{})
+	if aIsMap && bIsMap {
+		keys := map[string]bool{}
+		for k := range aMap {
+			keys[k] = true
+		}
+		for k := range bMap {
+			keys[k] = true
+		}
+		diffs := []string{}
+		for k := range keys {
+			childPath := k
+			if len(path) > 0 {
+				childPath = path + "." + k
+			}
+			av, aOk := aMap[k]
+			bv, bOk := bMap[k]
+			switch {
+			case !aOk:
+				diffs = append(diffs, fmt.Sprintf("%s: <missing> -> %v", childPath, bv))
+			case !bOk:
+				diffs = append(diffs, fmt.Sprintf("%s: %v -> <missing>", childPath, av))
+			default:
+				diffs = append(diffs, diffValues(childPath, av, bv)...)
+			}
+		}
+		return diffs
+	}
+
+	aSlice, aIsSlice := a.([]interface{})
+	bSlice, bIsSlice := b.([]interface{})
+	if aIsSlice && bIsSlice {
+		diffs := []string{}
+		max := len(aSlice)
+		if len(bSlice) > max {
+			max = len(bSlice)
+		}
+		for i := 0; i < max; i++ {
+			childPath := fmt.Sprintf("%s[%d]", path, i)
+			switch {
+			case i >= len(aSlice):
+				diffs = append(diffs, fmt.Sprintf("%s: <missing> -> %v", childPath, bSlice[i]))
+			case i >= len(bSlice):
+				diffs = append(diffs, fmt.Sprintf("%s: %v -> <missing>", childPath, aSlice[i]))
+			default:
+				diffs = append(diffs, diffValues(childPath, aSlice[i], bSlice[i])...)
+			}
+		}
+		return diffs
+	}
+
+	if fmt.Sprintf("%v", a) == fmt.Sprintf("%v", b) {
+		return nil
+	}
+	return []string{fmt.Sprintf("%s: %v -> %v", path, a, b)}
+}
+
+func replaceInfo(infos []*resource.Info, old, replacement *resource.Info) {
+	for i, info := range infos {
+		if info == old {
+			infos[i] = replacement
+			return
+		}
+	}
+}
+
+func joinLines(lines []string) string {
+	out := ""
+	for i, l := range lines {
+		if i > 0 {
+			out += "\n  "
+		}
+		out += l
+	}
+	return out
+}
+
+// dropOmittedFromContext removes any Info from byContext that isn't also
+// present in kept, so an object a kind Exporter or --exporter-plugin omitted
+// from the flattened pipeline (ErrExportOmit) doesn't still get printed raw
+// into its cluster's List by --group-by-cluster.
+func dropOmittedFromContext(byContext map[string][]*resource.Info, kept []*resource.Info) {
+	keptSet := map[*resource.Info]bool{}
+	for _, info := range kept {
+		keptSet[info] = true
+	}
+	for context, infos := range byContext {
+		filtered := infos[:0]
+		for _, info := range infos {
+			if keptSet[info] {
+				filtered = append(filtered, info)
+			}
+		}
+		byContext[context] = filtered
+	}
+}
+
+// groupInfosByCluster splits the per-context infos into the ordered list
+// used when --group-by-cluster is set, so each cluster's objects can be
+// wrapped in its own List inside the outer template.
+func groupInfosByCluster(byContext map[string][]*resource.Info, contexts []string) []struct {
+	Context string
+	Infos   []*resource.Info
+} {
+	groups := make([]struct {
+		Context string
+		Infos   []*resource.Info
+	}, 0, len(contexts))
+	for _, context := range contexts {
+		groups = append(groups, struct {
+			Context string
+			Infos   []*resource.Info
+		}{Context: context, Infos: byContext[context]})
+	}
+	return groups
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/meta"
+	"k8s.io/kubernetes/pkg/api/unversioned"
+	"k8s.io/kubernetes/pkg/kubectl/resource"
+)
+
+// configMapInfo builds a test Info the way infosForContexts does: tagged
+// with annotateSourceContext, so tests exercise the same annotation that
+// diffInfos has to see past in real use.
+func configMapInfo(context, data string) *resource.Info {
+	obj := &kapi.ConfigMap{
+		ObjectMeta: kapi.ObjectMeta{Namespace: "ns", Name: "config"},
+		Data:       map[string]string{"value": data},
+	}
+	annotateSourceContext(obj, context)
+	return &resource.Info{
+		Namespace: "ns",
+		Name:      "config",
+		Mapping:   &meta.RESTMapping{GroupVersionKind: unversioned.GroupVersionKind{Kind: "ConfigMap"}},
+		Object:    obj,
+	}
+}
+
+func TestMergeContextInfosFirst(t *testing.T) {
+	byContext := map[string][]*resource.Info{
+		"a": {configMapInfo("a", "from-a")},
+		"b": {configMapInfo("b", "from-b")},
+	}
+	merged, err := mergeContextInfos(byContext, []string{"a", "b"}, "first")
+	if err != nil {
+		t.Fatalf("mergeContextInfos: %v", err)
+	}
+	if len(merged) != 1 {
+		t.Fatalf("len(merged) = %d, want 1", len(merged))
+	}
+	if got := merged[0].Object.(*kapi.ConfigMap).Data["value"]; got != "from-a" {
+		t.Errorf("merged[0].Data[value] = %q, want %q", got, "from-a")
+	}
+}
+
+func TestMergeContextInfosLast(t *testing.T) {
+	byContext := map[string][]*resource.Info{
+		"a": {configMapInfo("a", "from-a")},
+		"b": {configMapInfo("b", "from-b")},
+	}
+	merged, err := mergeContextInfos(byContext, []string{"a", "b"}, "last")
+	if err != nil {
+		t.Fatalf("mergeContextInfos: %v", err)
+	}
+	if len(merged) != 1 {
+		t.Fatalf("len(merged) = %d, want 1", len(merged))
+	}
+	if got := merged[0].Object.(*kapi.ConfigMap).Data["value"]; got != "from-b" {
+		t.Errorf("merged[0].Data[value] = %q, want %q", got, "from-b")
+	}
+}
+
+func TestMergeContextInfosErrorIncludesFieldDiff(t *testing.T) {
+	byContext := map[string][]*resource.Info{
+		"a": {configMapInfo("a", "from-a")},
+		"b": {configMapInfo("b", "from-b")},
+	}
+	_, err := mergeContextInfos(byContext, []string{"a", "b"}, "error")
+	if err == nil {
+		t.Fatal("mergeContextInfos returned no error, want a conflict error")
+	}
+	if !strings.Contains(err.Error(), "data.value: from-a -> from-b") {
+		t.Errorf("error %q does not contain the expected field diff", err.Error())
+	}
+}
+
+func TestMergeContextInfosNoConflict(t *testing.T) {
+	byContext := map[string][]*resource.Info{
+		"a": {configMapInfo("a", "from-a")},
+	}
+	merged, err := mergeContextInfos(byContext, []string{"a"}, "error")
+	if err != nil {
+		t.Fatalf("mergeContextInfos: %v", err)
+	}
+	if len(merged) != 1 {
+		t.Fatalf("len(merged) = %d, want 1", len(merged))
+	}
+}
+
+// TestMergeContextInfosIdenticalAcrossContextsIsNotAConflict is the
+// regression case for the bug where annotateSourceContext's own annotation
+// - which necessarily differs per context - made every object present in
+// every cluster (a shared Namespace, a ServiceAccount) look like a conflict
+// even when nothing about the object itself differed.
+func TestMergeContextInfosIdenticalAcrossContextsIsNotAConflict(t *testing.T) {
+	byContext := map[string][]*resource.Info{
+		"a": {configMapInfo("a", "same")},
+		"b": {configMapInfo("b", "same")},
+	}
+	merged, err := mergeContextInfos(byContext, []string{"a", "b"}, "error")
+	if err != nil {
+		t.Fatalf("mergeContextInfos: %v", err)
+	}
+	if len(merged) != 1 {
+		t.Fatalf("len(merged) = %d, want 1", len(merged))
+	}
+}
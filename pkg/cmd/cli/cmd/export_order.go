@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"sort"
+
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	kapi "k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/kubectl/resource"
+)
+
+// InstallOrder decides whether a should be applied before b when re-creating
+// exported objects. orderInfos sorts with whichever InstallOrder it is
+// given, so an implementation decides the whole order, tiers included.
+// defaultInstallOrder breaks ties within a tier itself and does not consult
+// another InstallOrder.
+type InstallOrder interface {
+	Less(a, b *resource.Info) bool
+}
+
+// installTiers lists Kinds in the order they should be applied so that
+// dependent objects (a ServiceAccount a Pod references, a CRD a custom
+// resource instantiates) always land before their dependents. Kinds not
+// listed fall into an implicit final tier, ahead of nothing.
+var installTiers = []string{
+	"Namespace",
+	"CustomResourceDefinition",
+	"ServiceAccount", "Role", "RoleBinding", "ClusterRole", "ClusterRoleBinding",
+	"Secret", "ConfigMap",
+	"PersistentVolume", "PersistentVolumeClaim",
+	"Service",
+	"Deployment", "StatefulSet", "DaemonSet", "Job", "CronJob", "DeploymentConfig",
+}
+
+func installTierOf(kind string) int {
+	for i, k := range installTiers {
+		if k == kind {
+			return i
+		}
+	}
+	return len(installTiers)
+}
+
+// defaultInstallOrder implements InstallOrder using installTiers, with CRs
+// ordered immediately after their CRD, owner references breaking ties
+// within a tier, and (namespace, name) as the final, fully deterministic
+// tiebreaker.
+type defaultInstallOrder struct {
+	// crdKinds marks Kinds that were exported as a CustomResourceDefinition
+	// in this batch, so their instances can be placed right after the CRD
+	// tier instead of falling through to the catch-all tier.
+	crdKinds map[string]bool
+	// owners maps an object's key to the keys of objects that own it, so
+	// owners can be sorted ahead of what they own within a tier.
+	owners map[string][]string
+}
+
+func newDefaultInstallOrder(infos []*resource.Info) *defaultInstallOrder {
+	order := &defaultInstallOrder{crdKinds: map[string]bool{}, owners: map[string][]string{}}
+	for _, info := range infos {
+		if info.Mapping.GroupVersionKind.Kind != "CustomResourceDefinition" {
+			continue
+		}
+		crd, ok := info.Object.(*apiextensions.CustomResourceDefinition)
+		if !ok || len(crd.Spec.Names.Kind) == 0 {
+			continue
+		}
+		// tierOf looks this up by the Kind the CRD defines (e.g. "Foo"), not
+		// the CRD object's own name (e.g. "foos.example.com").
+		order.crdKinds[crd.Spec.Names.Kind] = true
+	}
+	for _, info := range infos {
+		objMeta, err := kapi.ObjectMetaFor(info.Object)
+		if err != nil {
+			continue
+		}
+		key := infoKey(info)
+		for _, ref := range objMeta.OwnerReferences {
+			order.owners[key] = append(order.owners[key], ref.Kind+"/"+ref.Name)
+		}
+	}
+	return order
+}
+
+func infoKey(info *resource.Info) string {
+	return info.Mapping.GroupVersionKind.Kind + "/" + info.Name
+}
+
+// tierOf spaces the listed tiers two apart, so that CR instances get a tier
+// of their own between the CRD tier and the one listed after it.
+func (o *defaultInstallOrder) tierOf(info *resource.Info) int {
+	kind := info.Mapping.GroupVersionKind.Kind
+	if o.crdKinds[kind] {
+		return 2*installTierOf("CustomResourceDefinition") + 1
+	}
+	return 2 * installTierOf(kind)
+}
+
+func (o *defaultInstallOrder) Less(a, b *resource.Info) bool {
+	tierA, tierB := o.tierOf(a), o.tierOf(b)
+	if tierA != tierB {
+		return tierA < tierB
+	}
+	aOwnsB := ownerListContains(o.owners[infoKey(b)], infoKey(a))
+	bOwnsA := ownerListContains(o.owners[infoKey(a)], infoKey(b))
+	if aOwnsB != bOwnsA {
+		// an owner should sort before what it owns
+		return aOwnsB
+	}
+	if a.Namespace != b.Namespace {
+		return a.Namespace < b.Namespace
+	}
+	return a.Name < b.Name
+}
+
+func ownerListContains(owners []string, key string) bool {
+	for _, o := range owners {
+		if o == key {
+			return true
+		}
+	}
+	return false
+}
+
+// byInstallOrder adapts InstallOrder to sort.Interface.
+type byInstallOrder struct {
+	infos []*resource.Info
+	order InstallOrder
+}
+
+func (s byInstallOrder) Len() int           { return len(s.infos) }
+func (s byInstallOrder) Swap(i, j int)      { s.infos[i], s.infos[j] = s.infos[j], s.infos[i] }
+func (s byInstallOrder) Less(i, j int) bool { return s.order.Less(s.infos[i], s.infos[j]) }
+
+// orderInfos sorts infos in place into install order using order, and
+// returns the same slice for convenience.
+func orderInfos(infos []*resource.Info, order InstallOrder) []*resource.Info {
+	sort.Stable(byInstallOrder{infos: infos, order: order})
+	return infos
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"testing"
+
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kubernetes/pkg/api/meta"
+	"k8s.io/kubernetes/pkg/api/unversioned"
+	"k8s.io/kubernetes/pkg/kubectl/resource"
+)
+
+func infoOfKind(kind, name string) *resource.Info {
+	return &resource.Info{
+		Namespace: "ns",
+		Name:      name,
+		Mapping:   &meta.RESTMapping{GroupVersionKind: unversioned.GroupVersionKind{Kind: kind}},
+	}
+}
+
+func TestOrderInfosByTier(t *testing.T) {
+	infos := []*resource.Info{
+		infoOfKind("Deployment", "app"),
+		infoOfKind("Service", "app"),
+		infoOfKind("Secret", "app-creds"),
+		infoOfKind("Namespace", "ns"),
+	}
+
+	ordered := orderInfos(infos, newDefaultInstallOrder(infos))
+
+	var kinds []string
+	for _, info := range ordered {
+		kinds = append(kinds, info.Mapping.GroupVersionKind.Kind)
+	}
+	want := []string{"Namespace", "Secret", "Service", "Deployment"}
+	for i, kind := range want {
+		if kinds[i] != kind {
+			t.Fatalf("ordered kinds = %v, want %v", kinds, want)
+		}
+	}
+}
+
+func TestOrderInfosBreaksTiesByNameWithinATier(t *testing.T) {
+	infos := []*resource.Info{
+		infoOfKind("Service", "b"),
+		infoOfKind("Service", "a"),
+	}
+
+	ordered := orderInfos(infos, newDefaultInstallOrder(infos))
+
+	if ordered[0].Name != "a" || ordered[1].Name != "b" {
+		t.Fatalf("ordered names = [%s, %s], want [a, b]", ordered[0].Name, ordered[1].Name)
+	}
+}
+
+func crdInfo(name, kind string) *resource.Info {
+	return &resource.Info{
+		Namespace: "ns",
+		Name:      name,
+		Mapping:   &meta.RESTMapping{GroupVersionKind: unversioned.GroupVersionKind{Kind: "CustomResourceDefinition"}},
+		Object: &apiextensions.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       apiextensions.CustomResourceDefinitionSpec{Names: apiextensions.CustomResourceDefinitionNames{Kind: kind}},
+		},
+	}
+}
+
+func TestOrderInfosPlacesCRInstancesAfterTheirCRD(t *testing.T) {
+	infos := []*resource.Info{
+		infoOfKind("Foo", "my-foo"),
+		infoOfKind("Deployment", "app"),
+		crdInfo("foos.example.com", "Foo"),
+		infoOfKind("Namespace", "ns"),
+	}
+
+	ordered := orderInfos(infos, newDefaultInstallOrder(infos))
+
+	var kinds []string
+	for _, info := range ordered {
+		kinds = append(kinds, info.Mapping.GroupVersionKind.Kind)
+	}
+	want := []string{"Namespace", "CustomResourceDefinition", "Foo", "Deployment"}
+	for i, kind := range want {
+		if kinds[i] != kind {
+			t.Fatalf("ordered kinds = %v, want %v", kinds, want)
+		}
+	}
+}
+
+func TestOrderInfosUnlistedKindSortsLast(t *testing.T) {
+	infos := []*resource.Info{
+		infoOfKind("SomeCustomKind", "x"),
+		infoOfKind("Namespace", "ns"),
+	}
+
+	ordered := orderInfos(infos, newDefaultInstallOrder(infos))
+
+	if ordered[0].Mapping.GroupVersionKind.Kind != "Namespace" {
+		t.Fatalf("ordered[0] = %s, want Namespace first", ordered[0].Mapping.GroupVersionKind.Kind)
+	}
+	if ordered[1].Mapping.GroupVersionKind.Kind != "SomeCustomKind" {
+		t.Fatalf("ordered[1] = %s, want SomeCustomKind last", ordered[1].Mapping.GroupVersionKind.Kind)
+	}
+}
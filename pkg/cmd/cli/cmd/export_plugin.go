@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"k8s.io/kubernetes/pkg/runtime"
+)
+
+// omitMarker is what a plugin writes to stdout, on its own, to ask that the
+// object be dropped from the export entirely.
+const omitMarker = "OMIT"
+
+// pluginExporter runs an out-of-process --exporter-plugin once per object:
+// it writes the object as JSON to the plugin's stdin and expects either the
+// transformed object as JSON, or the literal omitMarker, on stdout.
+type pluginExporter struct {
+	path string
+}
+
+func newPluginExporter(path string) *pluginExporter {
+	return &pluginExporter{path: path}
+}
+
+func (p *pluginExporter) Export(obj runtime.Object, exact bool) error {
+	request, err := json.Marshal(obj)
+	if err != nil {
+		return fmt.Errorf("--exporter-plugin: unable to encode object: %v", err)
+	}
+
+	cmd := exec.Command(p.path)
+	cmd.Stdin = bytes.NewReader(request)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("--exporter-plugin %s failed: %v: %s", p.path, err, strings.TrimSpace(stderr.String()))
+	}
+
+	response := bytes.TrimSpace(stdout.Bytes())
+	if string(response) == omitMarker {
+		return ErrExportOmit
+	}
+	if err := json.Unmarshal(response, obj); err != nil {
+		return fmt.Errorf("--exporter-plugin %s returned an object oc could not decode: %v", p.path, err)
+	}
+	return nil
+}
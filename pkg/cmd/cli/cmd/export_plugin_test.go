@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+)
+
+// writeTestPlugin writes body as an executable shell script and returns its
+// path, removing it when the test completes.
+func writeTestPlugin(t *testing.T, body string) string {
+	f, err := ioutil.TempFile("", "export-plugin-*.sh")
+	if err != nil {
+		t.Fatalf("unable to create test plugin: %v", err)
+	}
+	if _, err := f.WriteString("#!/bin/sh\n" + body); err != nil {
+		t.Fatalf("unable to write test plugin: %v", err)
+	}
+	f.Close()
+	if err := os.Chmod(f.Name(), 0755); err != nil {
+		t.Fatalf("unable to make test plugin executable: %v", err)
+	}
+	t.Cleanup(func() { os.Remove(f.Name()) })
+	return f.Name()
+}
+
+func TestPluginExporterOmit(t *testing.T) {
+	plugin := newPluginExporter(writeTestPlugin(t, "echo -n OMIT\n"))
+	if err := plugin.Export(&kapi.ConfigMap{}, false); err != ErrExportOmit {
+		t.Fatalf("Export() = %v, want ErrExportOmit", err)
+	}
+}
+
+func TestPluginExporterTransformsObject(t *testing.T) {
+	plugin := newPluginExporter(writeTestPlugin(t, "cat\n"))
+	obj := &kapi.ConfigMap{
+		ObjectMeta: kapi.ObjectMeta{Namespace: "ns", Name: "cfg"},
+		Data:       map[string]string{"a": "b"},
+	}
+	if err := plugin.Export(obj, false); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	if obj.Data["a"] != "b" {
+		t.Errorf("Export changed Data unexpectedly: %v", obj.Data)
+	}
+}
+
+func TestPluginExporterMissingBinaryErrors(t *testing.T) {
+	plugin := newPluginExporter("/nonexistent/export-plugin-binary")
+	if err := plugin.Export(&kapi.ConfigMap{}, false); err == nil {
+		t.Fatal("Export() with a nonexistent plugin returned no error")
+	}
+}
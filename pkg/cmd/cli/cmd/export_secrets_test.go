@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+)
+
+// TestSealUnsealSecretRoundTrip exercises sealSecret/unsealSecret with a
+// value larger than RSA-OAEP/SHA-256 could ever encrypt directly at 2048
+// bits (~190 bytes), to prove the hybrid AES-GCM/RSA envelope - not plain
+// RSA - is what's actually sealing the data.
+func TestSealUnsealSecretRoundTrip(t *testing.T) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("unable to generate test key: %v", err)
+	}
+
+	large := bytes.Repeat([]byte("a"), 4096)
+	secret := &kapi.Secret{
+		ObjectMeta: kapi.ObjectMeta{Namespace: "ns", Name: "big-secret"},
+		Type:       kapi.SecretTypeOpaque,
+		Data: map[string][]byte{
+			"tls.crt": large,
+			"short":   []byte("hello"),
+		},
+	}
+
+	sealed, err := sealSecret(secret, "sealed-secrets", &priv.PublicKey, "sha256:test")
+	if err != nil {
+		t.Fatalf("sealSecret: %v", err)
+	}
+	encrypted, ok := sealed.(*EncryptedSecret)
+	if !ok {
+		t.Fatalf("sealSecret returned %T, want *EncryptedSecret", sealed)
+	}
+	for k, v := range secret.Data {
+		if encrypted.Data[k] == string(v) {
+			t.Fatalf("data[%s] was not encrypted", k)
+		}
+	}
+
+	unsealed, err := unsealSecret(encrypted, priv)
+	if err != nil {
+		t.Fatalf("unsealSecret: %v", err)
+	}
+	restored, ok := unsealed.(*kapi.Secret)
+	if !ok {
+		t.Fatalf("unsealSecret returned %T, want *kapi.Secret", unsealed)
+	}
+	for k, want := range secret.Data {
+		if got := restored.Data[k]; !bytes.Equal(got, want) {
+			t.Errorf("data[%s] = %q, want %q", k, got, want)
+		}
+	}
+}
+
+// TestUnsealSecretWrongKey confirms a private key that doesn't match the one
+// a value was sealed against fails to decrypt rather than returning garbage.
+func TestUnsealSecretWrongKey(t *testing.T) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("unable to generate test key: %v", err)
+	}
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("unable to generate test key: %v", err)
+	}
+
+	secret := &kapi.Secret{
+		ObjectMeta: kapi.ObjectMeta{Namespace: "ns", Name: "secret"},
+		Data:       map[string][]byte{"key": []byte("value")},
+	}
+	sealed, err := sealSecret(secret, "sealed-secrets", &priv.PublicKey, "sha256:test")
+	if err != nil {
+		t.Fatalf("sealSecret: %v", err)
+	}
+
+	if _, err := unsealSecret(sealed, other); err == nil {
+		t.Fatal("unsealSecret with the wrong private key succeeded, want an error")
+	}
+}
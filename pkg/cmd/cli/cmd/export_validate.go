@@ -0,0 +1,212 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	kerrors "github.com/go-openapi/errors"
+	"github.com/go-openapi/spec"
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/validate"
+
+	openapiutil "k8s.io/kube-openapi/pkg/util"
+	"k8s.io/kubernetes/pkg/kubectl/resource"
+
+	"github.com/openshift/origin/pkg/cmd/util/clientcmd"
+)
+
+// strfmtRegistry is the format registry go-openapi/validate uses to check
+// fields like "date-time" or "byte". The default registry covers every
+// format Kubernetes' OpenAPI documents use.
+var strfmtRegistry = strfmt.Default
+
+// validateSchemaModes lists the values --validate-schema accepts.
+var validateSchemaModes = map[string]bool{
+	"strict": true,
+	"warn":   true,
+	"off":    true,
+}
+
+// schemaViolation describes a single mismatch between an exported object and
+// the server's published OpenAPI schema for its kind.
+type schemaViolation struct {
+	kind   string
+	name   string
+	path   string
+	reason string
+}
+
+func (v schemaViolation) String() string {
+	return fmt.Sprintf("%s %s: %s: %s", v.kind, v.name, v.path, v.reason)
+}
+
+// schemaCache fetches and caches the server's /openapi/v2 document for the
+// lifetime of a single `oc export` invocation, and resolves per-GVK
+// definitions out of it on demand.
+type schemaCache struct {
+	factory     *clientcmd.Factory
+	document    *spec.Swagger
+	definitions map[string]*spec.Schema
+}
+
+func newSchemaCache(f *clientcmd.Factory) *schemaCache {
+	return &schemaCache{factory: f, definitions: map[string]*spec.Schema{}}
+}
+
+func (c *schemaCache) fetch() error {
+	if c.document != nil {
+		return nil
+	}
+	client, err := c.factory.DiscoveryClient()
+	if err != nil {
+		return err
+	}
+	raw, err := client.Get().AbsPath("openapi", "v2").DoRaw()
+	if err != nil {
+		return fmt.Errorf("unable to fetch /openapi/v2: %v", err)
+	}
+	doc := &spec.Swagger{}
+	if err := json.Unmarshal(raw, doc); err != nil {
+		return fmt.Errorf("unable to parse /openapi/v2: %v", err)
+	}
+	c.document = doc
+	return nil
+}
+
+// schemaFor resolves the spec.Schema for the given GroupVersionKind, caching
+// the result so repeated kinds in one export only pay the lookup once.
+func (c *schemaCache) schemaFor(info *resource.Info) (*spec.Schema, error) {
+	gvk := info.Mapping.GroupVersionKind
+	name := openapiutil.ToRESTFriendlyName(fmt.Sprintf("%s/%s/%s", gvk.Group, gvk.Version, gvk.Kind))
+	if cached, ok := c.definitions[name]; ok {
+		return cached, nil
+	}
+	if err := c.fetch(); err != nil {
+		return nil, err
+	}
+	definition, ok := c.document.Definitions[name]
+	if !ok {
+		return nil, fmt.Errorf("no published schema for definition %q", name)
+	}
+	c.definitions[name] = &definition
+	return &definition, nil
+}
+
+// dropDefaults removes the fields that export unconditionally injects
+// (apiVersion, kind and most of metadata) before validating an object
+// against its schema, since those are not useful signal for this check.
+func dropDefaults(obj map[string]interface{}) map[string]interface{} {
+	cleaned := map[string]interface{}{}
+	for k, v := range obj {
+		switch k {
+		case "apiVersion", "kind":
+			continue
+		case "metadata":
+			if m, ok := v.(map[string]interface{}); ok {
+				meta := map[string]interface{}{}
+				if name, ok := m["name"]; ok {
+					meta["name"] = name
+				}
+				if namespace, ok := m["namespace"]; ok {
+					meta["namespace"] = namespace
+				}
+				cleaned[k] = meta
+				continue
+			}
+		}
+		cleaned[k] = v
+	}
+	return cleaned
+}
+
+// validateAgainstSchema checks a single exported object's JSON form against
+// its resolved OpenAPI schema, returning one schemaViolation per mismatch.
+func validateAgainstSchema(info *resource.Info, schema *spec.Schema) ([]schemaViolation, error) {
+	raw, err := json.Marshal(info.Object)
+	if err != nil {
+		return nil, err
+	}
+	var obj map[string]interface{}
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		return nil, err
+	}
+	cleaned, err := json.Marshal(dropDefaults(obj))
+	if err != nil {
+		return nil, err
+	}
+	var data interface{}
+	if err := json.Unmarshal(cleaned, &data); err != nil {
+		return nil, err
+	}
+
+	validator := validate.NewSchemaValidator(schema, nil, "", strfmtRegistry)
+	result := validator.Validate(data)
+	if result == nil || len(result.Errors) == 0 {
+		return nil, nil
+	}
+
+	violations := make([]schemaViolation, 0, len(result.Errors))
+	for _, e := range result.Errors {
+		path := ""
+		if verr, ok := e.(*kerrors.Validation); ok {
+			path = verr.Name
+		}
+		violations = append(violations, schemaViolation{
+			kind:   info.Mapping.GroupVersionKind.Kind,
+			name:   info.Name,
+			path:   path,
+			reason: e.Error(),
+		})
+	}
+	return violations, nil
+}
+
+// validateExportedSchemas validates every info against its server-published
+// schema and, depending on mode, either fails the export or just reports a
+// warning to the user.
+func validateExportedSchemas(f *clientcmd.Factory, infos []*resource.Info, mode string, out io.Writer) error {
+	if mode == "off" {
+		return nil
+	}
+	cache := newSchemaCache(f)
+	violations := []schemaViolation{}
+	for _, info := range infos {
+		if _, sealed := info.Object.(*EncryptedSecret); sealed {
+			// info.Mapping still reports the original Secret mapping here -
+			// the Builder never re-maps it once --encrypt-secrets swaps in an
+			// EncryptedSecret - but EncryptedSecret's provider/keyFingerprint/
+			// ciphertext shape has no published schema of its own and would
+			// never match Secret's, so skip it the same way a kind with no
+			// published schema at all is skipped below.
+			continue
+		}
+		schema, err := cache.schemaFor(info)
+		if err != nil {
+			// the server may simply not publish a schema for this kind (e.g. it
+			// predates CRD OpenAPI publishing); skip rather than fail the export.
+			continue
+		}
+		objViolations, err := validateAgainstSchema(info, schema)
+		if err != nil {
+			return err
+		}
+		violations = append(violations, objViolations...)
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+	lines := make([]string, 0, len(violations))
+	for _, v := range violations {
+		lines = append(lines, v.String())
+	}
+	sort.Strings(lines)
+	message := fmt.Sprintf("%d object(s) do not match their published schema:\n  %s", len(violations), strings.Join(lines, "\n  "))
+	if mode == "warn" {
+		fmt.Fprintln(out, message)
+		return nil
+	}
+	return fmt.Errorf(message)
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/go-openapi/spec"
+)
+
+func TestDropDefaultsStripsInjectedFields(t *testing.T) {
+	given := map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]interface{}{
+			"name":            "cfg",
+			"namespace":       "ns",
+			"resourceVersion": "123",
+			"uid":             "abc-def",
+			"selfLink":        "/api/v1/namespaces/ns/configmaps/cfg",
+		},
+		"data": map[string]interface{}{"key": "value"},
+	}
+
+	cleaned := dropDefaults(given)
+
+	if _, ok := cleaned["apiVersion"]; ok {
+		t.Error("dropDefaults left apiVersion in place")
+	}
+	if _, ok := cleaned["kind"]; ok {
+		t.Error("dropDefaults left kind in place")
+	}
+	meta, ok := cleaned["metadata"].(map[string]interface{})
+	if !ok {
+		t.Fatal("dropDefaults dropped metadata entirely")
+	}
+	if _, ok := meta["resourceVersion"]; ok {
+		t.Error("dropDefaults left metadata.resourceVersion in place")
+	}
+	if _, ok := meta["uid"]; ok {
+		t.Error("dropDefaults left metadata.uid in place")
+	}
+	if meta["name"] != "cfg" || meta["namespace"] != "ns" {
+		t.Errorf("dropDefaults changed metadata.name/namespace: %v", meta)
+	}
+	if cleaned["data"].(map[string]interface{})["key"] != "value" {
+		t.Error("dropDefaults altered a non-injected field")
+	}
+}
+
+func TestValidateAgainstSchemaReportsMissingRequiredField(t *testing.T) {
+	info := configMapInfo("a", "value")
+	schema := &spec.Schema{SchemaProps: spec.SchemaProps{Required: []string{"doesNotExist"}}}
+
+	violations, err := validateAgainstSchema(info, schema)
+	if err != nil {
+		t.Fatalf("validateAgainstSchema: %v", err)
+	}
+	if len(violations) == 0 {
+		t.Fatal("expected a violation for a required field the object doesn't have")
+	}
+}
+
+func TestValidateAgainstSchemaPassesWhenNothingIsRequired(t *testing.T) {
+	info := configMapInfo("a", "value")
+	schema := &spec.Schema{}
+
+	violations, err := validateAgainstSchema(info, schema)
+	if err != nil {
+		t.Fatalf("validateAgainstSchema: %v", err)
+	}
+	if len(violations) != 0 {
+		t.Errorf("unexpected violations against an unconstrained schema: %v", violations)
+	}
+}
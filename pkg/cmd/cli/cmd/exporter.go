@@ -0,0 +1,333 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/unversioned"
+	"k8s.io/kubernetes/pkg/kubectl/resource"
+	"k8s.io/kubernetes/pkg/runtime"
+
+	deployapi "github.com/openshift/origin/pkg/deploy/api"
+)
+
+// ErrExportOmit is a marker error that tells RunExport to silently drop the
+// current object from the exported output instead of treating it as a
+// failure. RunExport honors it from any Exporter, in-tree or plugin; of the
+// Exporters in this package, only the --exporter-plugin one returns it.
+var ErrExportOmit = fmt.Errorf("object is omitted")
+
+// Exporter transforms an object in place so that it is suitable for reuse -
+// for example by clearing fields that are assigned by the server on create,
+// or by masking values that should never leave the cluster they came from.
+type Exporter interface {
+	Export(obj runtime.Object, exact bool) error
+}
+
+// ExportAction is one row of what --explain-export prints: a single field
+// that a kind's Exporter would clear or preserve, and why.
+type ExportAction struct {
+	Kind   string
+	Field  string
+	Action string // "clear" or "preserve"
+	Reason string
+}
+
+// Explainer is implemented by Exporters that can describe, ahead of time,
+// which fields they touch and why. Every Exporter registered with
+// exporterRegistry implements it, since Register takes a kindExporter; the
+// --exporter-plugin Exporter does not, and its changes are not shown in
+// --explain-export output. exact must be the same value that will be passed to Export, since it
+// flips most rows between "clear" and "preserve".
+type Explainer interface {
+	Explain(exact bool) []ExportAction
+}
+
+// kindExporter is the shape every per-kind Exporter in exporterRegistry is
+// expected to satisfy: it both performs the export and can explain it.
+type kindExporter interface {
+	Exporter
+	Explainer
+}
+
+// exporterRegistry dispatches Export calls to a per-GVK Exporter, falling
+// back to clearing only the common ObjectMeta fields for kinds that don't
+// have a specific one registered. An optional plugin Exporter, loaded from
+// --exporter-plugin, runs after the in-tree exporter on every object.
+type exporterRegistry struct {
+	byKind   map[unversioned.GroupVersionKind]kindExporter
+	fallback kindExporter
+	plugin   Exporter
+}
+
+// newExporterRegistry builds the registry used by default (i.e. without
+// --exporter-plugin); call SetPlugin to attach an out-of-process exporter.
+func newExporterRegistry() *exporterRegistry {
+	r := &exporterRegistry{
+		byKind:   map[unversioned.GroupVersionKind]kindExporter{},
+		fallback: &metadataExporter{},
+	}
+	r.Register(kapi.SchemeGroupVersion.WithKind("Service"), &serviceExporter{})
+	r.Register(kapi.SchemeGroupVersion.WithKind("Pod"), &podExporter{})
+	r.Register(kapi.SchemeGroupVersion.WithKind("PersistentVolumeClaim"), &pvcExporter{})
+	r.Register(kapi.SchemeGroupVersion.WithKind("ReplicationController"), &replicationControllerExporter{})
+	r.Register(kapi.SchemeGroupVersion.WithKind("Secret"), &secretExporter{})
+	r.Register(deployapi.SchemeGroupVersion.WithKind("DeploymentConfig"), &deploymentConfigExporter{})
+	return r
+}
+
+// Register adds or replaces the Exporter used for gvk. Operators shipping a
+// new CRD can call this (or, more commonly, ship a --exporter-plugin) to
+// contribute export rules without recompiling oc.
+func (r *exporterRegistry) Register(gvk unversioned.GroupVersionKind, exporter kindExporter) {
+	r.byKind[gvk] = exporter
+}
+
+// SetPlugin attaches an out-of-process exporter that runs after the in-tree
+// one on every object.
+func (r *exporterRegistry) SetPlugin(plugin Exporter) {
+	r.plugin = plugin
+}
+
+func (r *exporterRegistry) exporterFor(obj runtime.Object) kindExporter {
+	gvks, _, err := kapi.Scheme.ObjectKinds(obj)
+	if err == nil {
+		for _, gvk := range gvks {
+			if e, ok := r.byKind[gvk]; ok {
+				return e
+			}
+		}
+	}
+	return r.fallback
+}
+
+func (r *exporterRegistry) Export(obj runtime.Object, exact bool) error {
+	if err := r.exporterFor(obj).Export(obj, exact); err != nil {
+		return err
+	}
+	if r.plugin != nil {
+		return r.plugin.Export(obj, exact)
+	}
+	return nil
+}
+
+// explain returns the (kind, field, action, reason) rows --explain-export
+// prints for obj: the metadata rows common to every kind, plus whatever the
+// specific registered Exporter (if any) adds. exact must match the --exact
+// flag that would be passed to Export, since it changes whether each field
+// is cleared or preserved.
+func (r *exporterRegistry) explain(obj runtime.Object, exact bool) []ExportAction {
+	exporter := r.exporterFor(obj)
+	if exporter == r.fallback {
+		return exporter.Explain(exact)
+	}
+	return append(r.fallback.Explain(exact), exporter.Explain(exact)...)
+}
+
+// clearObjectMeta removes the ObjectMeta fields that are assigned by the
+// server on create and therefore unsafe to reuse across clusters, unless
+// --exact is requested.
+func clearObjectMeta(obj runtime.Object, exact bool) error {
+	objMeta, err := kapi.ObjectMetaFor(obj)
+	if err != nil {
+		return err
+	}
+	if exact {
+		return nil
+	}
+	objMeta.ResourceVersion = ""
+	objMeta.UID = ""
+	objMeta.SelfLink = ""
+	objMeta.CreationTimestamp = unversioned.Time{}
+	objMeta.DeletionTimestamp = nil
+	objMeta.Generation = 0
+	return nil
+}
+
+// withKind stamps a Kind onto an ExportAction built by clearedField, so
+// metadataExportActions rows (which apply to every kind) can stay Kind-less
+// while per-kind rows carry their own.
+func withKind(kind string, action ExportAction) ExportAction {
+	action.Kind = kind
+	return action
+}
+
+// clearedField builds the ExportAction for a field that clearObjectMeta (or
+// a kind-specific Exporter) clears when exact is false, and leaves alone
+// when exact is true.
+func clearedField(field, clearReason string, exact bool) ExportAction {
+	if exact {
+		return ExportAction{Field: field, Action: "preserve", Reason: "--exact was set, so this field is left as-is"}
+	}
+	return ExportAction{Field: field, Action: "clear", Reason: clearReason}
+}
+
+func metadataExportActions(exact bool) []ExportAction {
+	return []ExportAction{
+		clearedField("metadata.resourceVersion", "assigned by the server on every write", exact),
+		clearedField("metadata.uid", "assigned by the server on create", exact),
+		clearedField("metadata.selfLink", "derived from the server's API path", exact),
+		clearedField("metadata.creationTimestamp", "assigned by the server on create", exact),
+		clearedField("metadata.deletionTimestamp", "set by the server when deletion is requested", exact),
+		clearedField("metadata.generation", "assigned by the server on every spec change", exact),
+	}
+}
+
+// metadataExporter is the fallback used for kinds with no kind-specific
+// Exporter registered: it only clears the common ObjectMeta fields.
+type metadataExporter struct{}
+
+func (e *metadataExporter) Export(obj runtime.Object, exact bool) error {
+	return clearObjectMeta(obj, exact)
+}
+
+func (e *metadataExporter) Explain(exact bool) []ExportAction {
+	return metadataExportActions(exact)
+}
+
+type serviceExporter struct{}
+
+func (e *serviceExporter) Export(obj runtime.Object, exact bool) error {
+	if err := clearObjectMeta(obj, exact); err != nil {
+		return err
+	}
+	svc, ok := obj.(*kapi.Service)
+	if !ok || exact {
+		return nil
+	}
+	svc.Spec.ClusterIP = ""
+	if svc.Spec.Type == kapi.ServiceTypeNodePort {
+		for i := range svc.Spec.Ports {
+			svc.Spec.Ports[i].NodePort = 0
+		}
+	}
+	return nil
+}
+
+func (e *serviceExporter) Explain(exact bool) []ExportAction {
+	return []ExportAction{
+		withKind("Service", clearedField("spec.clusterIP", "allocated per-cluster; reusing it elsewhere would likely conflict", exact)),
+		withKind("Service", clearedField("spec.ports[].nodePort", "allocated from the destination cluster's node port range; cleared only for services of type NodePort", exact)),
+	}
+}
+
+type podExporter struct{}
+
+func (e *podExporter) Export(obj runtime.Object, exact bool) error {
+	if err := clearObjectMeta(obj, exact); err != nil {
+		return err
+	}
+	pod, ok := obj.(*kapi.Pod)
+	if !ok || exact {
+		return nil
+	}
+	pod.Spec.NodeName = ""
+	pod.Status = kapi.PodStatus{}
+	return nil
+}
+
+func (e *podExporter) Explain(exact bool) []ExportAction {
+	return []ExportAction{
+		withKind("Pod", clearedField("spec.nodeName", "assigned by the scheduler on the source cluster", exact)),
+		withKind("Pod", clearedField("status", "reported by the kubelet and meaningless without the source run", exact)),
+	}
+}
+
+type pvcExporter struct{}
+
+func (e *pvcExporter) Export(obj runtime.Object, exact bool) error {
+	if err := clearObjectMeta(obj, exact); err != nil {
+		return err
+	}
+	pvc, ok := obj.(*kapi.PersistentVolumeClaim)
+	if !ok || exact {
+		return nil
+	}
+	pvc.Spec.VolumeName = ""
+	return nil
+}
+
+func (e *pvcExporter) Explain(exact bool) []ExportAction {
+	return []ExportAction{
+		withKind("PersistentVolumeClaim", clearedField("spec.volumeName", "bound to a PersistentVolume that only exists on the source cluster", exact)),
+	}
+}
+
+type replicationControllerExporter struct{}
+
+func (e *replicationControllerExporter) Export(obj runtime.Object, exact bool) error {
+	if err := clearObjectMeta(obj, exact); err != nil {
+		return err
+	}
+	rc, ok := obj.(*kapi.ReplicationController)
+	if !ok || exact {
+		return nil
+	}
+	rc.Status = kapi.ReplicationControllerStatus{}
+	return nil
+}
+
+func (e *replicationControllerExporter) Explain(exact bool) []ExportAction {
+	return []ExportAction{
+		withKind("ReplicationController", clearedField("status", "reported by the controller manager and meaningless without the source run", exact)),
+	}
+}
+
+// secretExporter has no field-level behavior of its own; Secret contents
+// are instead handled by the --encrypt-secrets pipeline in export_secrets.go.
+// It exists so that Explain() has something accurate to say about Secrets.
+type secretExporter struct{}
+
+func (e *secretExporter) Export(obj runtime.Object, exact bool) error {
+	return clearObjectMeta(obj, exact)
+}
+
+func (e *secretExporter) Explain(exact bool) []ExportAction {
+	return []ExportAction{
+		{Kind: "Secret", Field: "data, stringData", Action: "preserve", Reason: "export never alters Secret contents directly; use --encrypt-secrets to seal them"},
+	}
+}
+
+// deploymentConfigExporter clears the status the deployment controller
+// reports back, including latestVersion - the field exportLong's help text
+// has always promised export clears, same as a service's clusterIP.
+type deploymentConfigExporter struct{}
+
+func (e *deploymentConfigExporter) Export(obj runtime.Object, exact bool) error {
+	if err := clearObjectMeta(obj, exact); err != nil {
+		return err
+	}
+	dc, ok := obj.(*deployapi.DeploymentConfig)
+	if !ok || exact {
+		return nil
+	}
+	dc.Status = deployapi.DeploymentConfigStatus{}
+	return nil
+}
+
+func (e *deploymentConfigExporter) Explain(exact bool) []ExportAction {
+	return []ExportAction{
+		withKind("DeploymentConfig", clearedField("status", "reported by the deployment controller, including the current latestVersion, and meaningless without the source run", exact)),
+	}
+}
+
+// printExportExplanation is what --explain-export prints instead of the
+// exported objects: a table of every field each input's Exporter would
+// clear or preserve, and why.
+func printExportExplanation(infos []*resource.Info, registry *exporterRegistry, exact bool, out io.Writer) error {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "KIND\tNAME\tFIELD\tACTION\tREASON")
+	for _, info := range infos {
+		kind := info.Mapping.GroupVersionKind.Kind
+		for _, action := range registry.explain(info.Object, exact) {
+			field := action.Field
+			if len(action.Kind) == 0 {
+				action.Kind = kind
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", action.Kind, info.Name, field, action.Action, action.Reason)
+		}
+	}
+	return w.Flush()
+}
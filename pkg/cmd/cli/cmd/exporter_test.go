@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"testing"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/unversioned"
+
+	deployapi "github.com/openshift/origin/pkg/deploy/api"
+)
+
+// unregisteredKind stands in for an object of a kind the registry has never
+// seen, so exporterFor/explain must fall back to the bare metadata handling.
+type unregisteredKind struct {
+	unversioned.TypeMeta
+	kapi.ObjectMeta
+}
+
+func (u *unregisteredKind) GetObjectKind() unversioned.ObjectKind { return &u.TypeMeta }
+
+func TestClearedFieldReflectsExact(t *testing.T) {
+	cleared := clearedField("spec.foo", "some reason", false)
+	if cleared.Action != "clear" || cleared.Reason != "some reason" {
+		t.Errorf("clearedField(..., false) = %+v, want action clear with the given reason", cleared)
+	}
+
+	preserved := clearedField("spec.foo", "some reason", true)
+	if preserved.Action != "preserve" {
+		t.Errorf("clearedField(..., true) = %+v, want action preserve", preserved)
+	}
+}
+
+func TestWithKindStampsKind(t *testing.T) {
+	action := withKind("Service", clearedField("spec.clusterIP", "reason", false))
+	if action.Kind != "Service" {
+		t.Errorf("withKind did not stamp Kind: %+v", action)
+	}
+}
+
+func TestExplainFallsBackForUnregisteredKinds(t *testing.T) {
+	registry := newExporterRegistry()
+	rows := registry.explain(&unregisteredKind{}, false)
+	want := metadataExportActions(false)
+	if len(rows) != len(want) {
+		t.Fatalf("explain() returned %d rows, want %d (metadata-only)", len(rows), len(want))
+	}
+}
+
+func TestDeploymentConfigExporterClearsStatus(t *testing.T) {
+	dc := &deployapi.DeploymentConfig{
+		ObjectMeta: kapi.ObjectMeta{Namespace: "ns", Name: "app"},
+		Status:     deployapi.DeploymentConfigStatus{LatestVersion: 4},
+	}
+
+	if err := (&deploymentConfigExporter{}).Export(dc, false); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	if dc.Status.LatestVersion != 0 {
+		t.Errorf("Status.LatestVersion = %d, want 0 after export", dc.Status.LatestVersion)
+	}
+}
+
+func TestDeploymentConfigExporterPreservesStatusWhenExact(t *testing.T) {
+	dc := &deployapi.DeploymentConfig{
+		ObjectMeta: kapi.ObjectMeta{Namespace: "ns", Name: "app"},
+		Status:     deployapi.DeploymentConfigStatus{LatestVersion: 4},
+	}
+
+	if err := (&deploymentConfigExporter{}).Export(dc, true); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	if dc.Status.LatestVersion != 4 {
+		t.Errorf("Status.LatestVersion = %d, want 4 preserved under --exact", dc.Status.LatestVersion)
+	}
+}